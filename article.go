@@ -0,0 +1,208 @@
+package goscraper
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// wordsPerMinute is the reading speed used to estimate Article.ReadingTime.
+const wordsPerMinute = 200
+
+// Article is the result of a readability-style content extraction: the
+// highest-scoring content node on the page, sanitized and rendered both as
+// HTML and plaintext.
+type Article struct {
+	HTML        string
+	Text        string
+	ReadingTime time.Duration
+}
+
+// SanitizePolicy controls which tags and attributes survive article
+// extraction. Tags not in AllowedTags are dropped but their text is kept;
+// <script> and <style> elements are dropped along with their content.
+// Attributes not in AllowedAttributes are dropped. Ads are not detected:
+// text inside the chosen content node is kept whatever element holds it.
+type SanitizePolicy struct {
+	AllowedTags       map[string]bool
+	AllowedAttributes map[string]bool
+}
+
+// DefaultSanitizePolicy keeps the small set of tags and attributes needed to
+// preserve an article's structure.
+var DefaultSanitizePolicy = SanitizePolicy{
+	AllowedTags: map[string]bool{
+		"p": true, "a": true, "b": true, "strong": true, "i": true, "em": true,
+		"ul": true, "ol": true, "li": true, "blockquote": true, "br": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"img": true, "figure": true, "figcaption": true, "pre": true, "code": true,
+	},
+	AllowedAttributes: map[string]bool{
+		"href": true, "src": true, "alt": true, "title": true,
+	},
+}
+
+func (scraper *Scraper) sanitizePolicy() SanitizePolicy {
+	if scraper.Sanitize != nil {
+		return *scraper.Sanitize
+	}
+	return DefaultSanitizePolicy
+}
+
+// extractArticle scores <article>/<main>/<div>/<section> nodes in raw by
+// paragraph density, picks the highest-scoring one (falling back to <body>),
+// and renders it through policy into sanitized HTML plus plaintext.
+func extractArticle(raw []byte, policy SanitizePolicy) Article {
+	root, err := html.Parse(bytes.NewReader(raw))
+	if err != nil || root == nil {
+		return Article{}
+	}
+
+	candidate := bestCandidate(root)
+	if candidate == nil {
+		return Article{}
+	}
+
+	var htmlBuf, textBuf strings.Builder
+	renderSanitized(candidate, policy, &htmlBuf, &textBuf)
+	text := strings.Join(strings.Fields(textBuf.String()), " ")
+
+	return Article{
+		HTML:        htmlBuf.String(),
+		Text:        text,
+		ReadingTime: readingTime(text),
+	}
+}
+
+func bestCandidate(root *html.Node) *html.Node {
+	var best, body *html.Node
+	var bestScore float64
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "body":
+				body = n
+			case "script", "style", "nav", "header", "footer", "aside":
+				return
+			case "article", "main", "div", "section":
+				if score := paragraphDensity(n); score > bestScore {
+					bestScore = score
+					best = n
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	if best != nil {
+		return best
+	}
+	return body
+}
+
+// paragraphDensity scores a node by the length of the <p> text it directly
+// contains, the same heuristic readability-style extractors use to tell a
+// page's main content apart from nav/sidebar/comment boilerplate.
+func paragraphDensity(n *html.Node) float64 {
+	var score float64
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "p" {
+			if text := strings.TrimSpace(textContent(c)); len(text) > 25 {
+				score += 1 + float64(len(text))/200
+			}
+		}
+	}
+	return score
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		buf.WriteString(textContent(c))
+	}
+	return buf.String()
+}
+
+func renderSanitized(n *html.Node, policy SanitizePolicy, htmlBuf, textBuf *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		htmlBuf.WriteString(html.EscapeString(n.Data))
+		textBuf.WriteString(n.Data)
+		textBuf.WriteByte(' ')
+		return
+	case html.ElementNode:
+		if n.Data == "script" || n.Data == "style" {
+			return
+		}
+		allowed := policy.AllowedTags[n.Data]
+		if allowed {
+			htmlBuf.WriteString("<" + n.Data)
+			for _, attr := range n.Attr {
+				if policy.AllowedAttributes[attr.Key] && isSafeAttrValue(attr.Key, attr.Val) {
+					htmlBuf.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+				}
+			}
+			htmlBuf.WriteString(">")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderSanitized(c, policy, htmlBuf, textBuf)
+		}
+		if allowed {
+			htmlBuf.WriteString("</" + n.Data + ">")
+		}
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderSanitized(c, policy, htmlBuf, textBuf)
+	}
+}
+
+// unsafeURLSchemes are URI schemes rejected on href/src attribute values,
+// since DefaultSanitizePolicy's tag/attribute allowlist doesn't otherwise
+// stop a javascript:/data: URI from reaching Article.HTML.
+var unsafeURLSchemes = []string{"javascript:", "data:", "vbscript:"}
+
+// asciiTabOrNewlineReplacer strips ASCII tab and newline characters, which
+// browsers remove from a URL before parsing it (WHATWG URL spec, "remove all
+// ASCII tab or newline" step). Without this, a scheme like "jav\tascript:"
+// reads as safe to a plain prefix check but still parses as javascript: once
+// rendered.
+var asciiTabOrNewlineReplacer = strings.NewReplacer("\t", "", "\n", "", "\r", "")
+
+// isSafeAttrValue reports whether value is safe to carry through to
+// Article.HTML unchanged. Only href and src are scheme-checked; other
+// allowlisted attributes (alt, title, ...) hold plain text, not URIs.
+func isSafeAttrValue(key, value string) bool {
+	if key != "href" && key != "src" {
+		return true
+	}
+	lower := strings.ToLower(strings.TrimSpace(asciiTabOrNewlineReplacer.Replace(value)))
+	for _, scheme := range unsafeURLSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return false
+		}
+	}
+	return true
+}
+
+func readingTime(text string) time.Duration {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0
+	}
+	minutes := words / wordsPerMinute
+	if minutes < 1 {
+		minutes = 1
+	}
+	return time.Duration(minutes) * time.Minute
+}
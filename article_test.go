@@ -0,0 +1,33 @@
+package goscraper
+
+import "testing"
+
+func TestIsSafeAttrValue(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		value string
+		want  bool
+	}{
+		{"plain http href", "href", "http://example.com", true},
+		{"plain https href", "href", "https://example.com/a", true},
+		{"relative href", "href", "/a/b", true},
+		{"mailto href", "href", "mailto:a@example.com", true},
+		{"javascript scheme", "href", "javascript:alert(1)", false},
+		{"uppercase javascript scheme", "href", "JavaScript:alert(1)", false},
+		{"leading whitespace javascript scheme", "href", "  javascript:alert(1)", false},
+		{"tab-split javascript scheme", "href", "jav\tascript:alert(1)", false},
+		{"newline-split javascript scheme", "href", "jav\nascript:alert(1)", false},
+		{"carriage-return-split javascript scheme", "href", "jav\rascript:alert(1)", false},
+		{"data scheme", "src", "data:text/html,<script>alert(1)</script>", false},
+		{"vbscript scheme", "href", "vbscript:msgbox(1)", false},
+		{"non-URL attribute ignores scheme text", "alt", "javascript:alert(1)", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isSafeAttrValue(c.key, c.value); got != c.want {
+				t.Errorf("isSafeAttrValue(%q, %q) = %v, want %v", c.key, c.value, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package goscraper
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"regexp"
+
+	"golang.org/x/net/html/charset"
+)
+
+// charsetSniffLen is how much of the response body is buffered and handed
+// to charset detection before the rest streams through.
+const charsetSniffLen = 1024
+
+// metaCharsetRegexp matches both <meta charset="..."> and the
+// <meta http-equiv="Content-Type" content="text/html; charset=..."> form.
+var metaCharsetRegexp = regexp.MustCompile(`(?i)<meta[^>]+charset=["']?([a-zA-Z0-9_\-]+)`)
+
+// CharsetDetector is a pluggable statistical detector (e.g. chardet),
+// consulted when Scraper.convertUTF8 can't determine a page's encoding from
+// its Content-Type header or a <meta charset> sniff.
+type CharsetDetector interface {
+	DetectEncoding(preview []byte, contentType string) (charsetName string, err error)
+}
+
+// convertUTF8 copies content into a buffer, transcoding it to UTF-8. The
+// encoding is chosen, in order, from: a byte-order mark detected by
+// charset.DetermineEncoding, which is unambiguous evidence of the encoding
+// and must win over a conflicting or stale Content-Type header; the
+// Content-Type header's charset parameter; a <meta charset>/<meta
+// http-equiv> tag sniffed from the first charsetSniffLen bytes (needed for
+// pages that omit or lie about charset in their header); scraper.CharsetDetector,
+// if set; and finally charset.DetermineEncoding's own content-sniffing
+// heuristics.
+func (scraper *Scraper) convertUTF8(content io.Reader, contentType string) (bytes.Buffer, error) {
+	buff := bytes.Buffer{}
+
+	preview := make([]byte, charsetSniffLen)
+	n, err := io.ReadFull(content, preview)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return buff, err
+	}
+	preview = preview[:n]
+	rest := io.MultiReader(bytes.NewReader(preview), content)
+
+	reader, err := charsetReader(scraper, rest, preview, contentType)
+	if err != nil {
+		return buff, err
+	}
+
+	_, err = io.Copy(&buff, reader)
+	if err != nil {
+		return buff, err
+	}
+	return buff, nil
+}
+
+func charsetReader(scraper *Scraper, content io.Reader, preview []byte, contentType string) (io.Reader, error) {
+	name := scraper.detectCharset(preview, contentType)
+	if name == "" || name == "utf-8" {
+		return content, nil
+	}
+	enc, _ := charset.Lookup(name)
+	if enc == nil {
+		return content, nil
+	}
+	return enc.NewDecoder().Reader(content), nil
+}
+
+func (scraper *Scraper) detectCharset(preview []byte, contentType string) string {
+	// certain is true when DetermineEncoding found a BOM, which takes
+	// priority over a Content-Type header or <meta charset> tag that may be
+	// wrong, or when the Content-Type charset names an encoding it knows.
+	_, sniffedName, certain := charset.DetermineEncoding(preview, contentType)
+	if certain {
+		return sniffedName
+	}
+	if name := headerCharset(contentType); name != "" {
+		return name
+	}
+	if name := sniffMetaCharset(preview); name != "" {
+		return name
+	}
+	if scraper.CharsetDetector != nil {
+		if name, err := scraper.CharsetDetector.DetectEncoding(preview, contentType); err == nil && name != "" {
+			return name
+		}
+	}
+	return sniffedName
+}
+
+func headerCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+func sniffMetaCharset(preview []byte) string {
+	matches := metaCharsetRegexp.FindSubmatch(preview)
+	if matches == nil {
+		return ""
+	}
+	return string(matches[1])
+}
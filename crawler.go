@@ -0,0 +1,209 @@
+package goscraper
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CrawlResult pairs the URL that was scraped with its resulting Document, or
+// the error encountered while scraping it.
+type CrawlResult struct {
+	URL      string
+	Document *Document
+	Err      error
+}
+
+// CrawlMetrics tallies counters across a Crawler's lifetime.
+type CrawlMetrics struct {
+	Fetched uint64
+	Skipped uint64
+	Errored uint64
+	Bytes   uint64
+}
+
+// Crawler wraps a Scraper configuration and fans a batch of URLs out across
+// a worker pool, honoring a per-host rate limit and deduplicating repeated
+// URLs via a seencheck set, as archival crawlers like Zeno do. The zero value
+// is ready to use, like Scraper.
+type Crawler struct {
+	// Scraper is copied for each URL scraped; its Url, Target and
+	// EscapedFragmentUrl fields are overwritten per request.
+	Scraper Scraper
+	// Workers caps the number of URLs scraped concurrently. Zero defaults
+	// to 4.
+	Workers int
+	// PerHostRPS caps requests per second to a single host, via a token
+	// bucket keyed by URL.Host. Zero disables per-host rate limiting.
+	PerHostRPS float64
+
+	once sync.Once
+
+	mu      sync.Mutex
+	metrics CrawlMetrics
+
+	seenMu sync.Mutex
+	seen   map[string]bool
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rateLimiter
+}
+
+// Metrics returns a snapshot of the Crawler's counters.
+func (c *Crawler) Metrics() CrawlMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metrics
+}
+
+// Crawl dispatches urls across the worker pool and returns a channel of
+// results, one per unique URL. The channel is closed once every URL has been
+// scraped or skipped. Repeated URLs (already seen by this Crawler) are
+// skipped and counted in Metrics().Skipped rather than sent to results.
+func (c *Crawler) Crawl(urls []string) <-chan CrawlResult {
+	c.init()
+
+	workers := c.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	jobs := make(chan string)
+	results := make(chan CrawlResult)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				results <- c.scrapeOne(u)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, u := range urls {
+			if !c.markSeen(u) {
+				c.recordSkipped()
+				continue
+			}
+			jobs <- u
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+func (c *Crawler) init() {
+	c.once.Do(func() {
+		c.seen = make(map[string]bool)
+		c.limiters = make(map[string]*rateLimiter)
+	})
+}
+
+func (c *Crawler) markSeen(rawurl string) bool {
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	if c.seen[rawurl] {
+		return false
+	}
+	c.seen[rawurl] = true
+	return true
+}
+
+func (c *Crawler) scrapeOne(rawurl string) CrawlResult {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		c.recordErrored()
+		return CrawlResult{URL: rawurl, Err: err}
+	}
+
+	c.limiterFor(u.Host).Wait()
+
+	scraper := c.Scraper
+	scraper.Url = u
+	scraper.Target = u
+	scraper.EscapedFragmentUrl = nil
+
+	doc, err := scraper.Scrape()
+	if err != nil {
+		c.recordErrored()
+		return CrawlResult{URL: rawurl, Err: err}
+	}
+
+	c.mu.Lock()
+	c.metrics.Fetched++
+	c.metrics.Bytes += uint64(doc.Bytes)
+	c.mu.Unlock()
+
+	return CrawlResult{URL: rawurl, Document: doc}
+}
+
+func (c *Crawler) recordSkipped() {
+	c.mu.Lock()
+	c.metrics.Skipped++
+	c.mu.Unlock()
+}
+
+func (c *Crawler) recordErrored() {
+	c.mu.Lock()
+	c.metrics.Errored++
+	c.mu.Unlock()
+}
+
+func (c *Crawler) limiterFor(host string) *rateLimiter {
+	c.limitersMu.Lock()
+	defer c.limitersMu.Unlock()
+	l, ok := c.limiters[host]
+	if !ok {
+		l = newRateLimiter(c.PerHostRPS)
+		c.limiters[host] = l
+	}
+	return l
+}
+
+// rateLimiter is a simple token bucket: Wait blocks until a token is
+// available, refilling at rate tokens per second up to a burst of rate
+// tokens. A non-positive rate disables throttling.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+	return &rateLimiter{rate: rps, tokens: rps, last: time.Now()}
+}
+
+func (l *rateLimiter) Wait() {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		time.Sleep(wait)
+		l.tokens = 0
+		l.last = time.Now()
+		return
+	}
+	l.tokens--
+}
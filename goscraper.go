@@ -2,20 +2,21 @@ package goscraper
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
-	"golang.org/x/net/html/charset"
 )
 
 var (
-	EscapedFragment string = "_escaped_fragment_="
-	fragmentRegexp         = regexp.MustCompile("#!(.*)")
+	EscapedFragment   string = "_escaped_fragment_="
+	fragmentRegexp           = regexp.MustCompile("#!(.*)")
+	metaRefreshRegexp        = regexp.MustCompile(`(?i)^\s*[0-9.]*\s*;?\s*url\s*=\s*['"]?([^'">]+)['"]?\s*$`)
 )
 
 type Scraper struct {
@@ -25,11 +26,66 @@ type Scraper struct {
 	MaxRedirect        int
 	Authorization      string
 	Language           string
+
+	// RespectRobots makes Scrape consult robots.txt for scraper.Url's host
+	// before fetching, refusing disallowed URLs and pacing requests to the
+	// same host according to any Crawl-delay directive.
+	RespectRobots bool
+	// Robots is the cache consulted when RespectRobots is set. If nil, a
+	// package-level cache shared across all Scrapers is used instead.
+	Robots *RobotsCache
+
+	// Client is used for every request the Scraper makes (the page fetch,
+	// robots.txt, and oEmbed lookups). A nil Client falls back to
+	// http.DefaultClient.
+	Client *http.Client
+	// Ctx bounds every request's lifetime. A nil Ctx falls back to
+	// context.Background().
+	Ctx context.Context
+	// UserAgent overrides the default "GoScraper" User-Agent header.
+	UserAgent string
+	// Headers are added to every page request, after the Authorization
+	// cookie, Accept-Language and User-Agent headers are set. They are not
+	// sent with the robots.txt or oEmbed fetches.
+	Headers http.Header
+
+	// ExtractArticle enables readability-style extraction of the page's
+	// main content into Document.Article.
+	ExtractArticle bool
+	// Sanitize overrides DefaultSanitizePolicy for the tags and attributes
+	// kept during article extraction.
+	Sanitize *SanitizePolicy
+
+	// CharsetDetector is consulted when the Content-Type header and a
+	// <meta charset> sniff both fail to identify a page's encoding. Plug in
+	// a statistical detector (e.g. chardet) for the hardest cases.
+	CharsetDetector CharsetDetector
+
+	// FetchOEmbed enables fetching a page's <link rel="alternate"
+	// type="application/json+oembed"> target to fill in Preview.Author,
+	// Title, Images and Video. It's opt-in because the URL comes straight from page
+	// content, making it an extra outbound request to an untrusted host.
+	FetchOEmbed bool
+
+	// rawBody holds the most recently fetched page body for article
+	// extraction, since parseDocument consumes Document.Body as it scans
+	// for head metadata.
+	rawBody []byte
+
+	// visitedUrls guards meta-refresh handling against redirect loops.
+	visitedUrls map[string]bool
 }
 
 type Document struct {
 	Body    bytes.Buffer
 	Preview DocumentPreview
+	// Article is populated when Scraper.ExtractArticle is set.
+	Article Article
+	// Bytes is the size of the fetched, decoded body. It's captured
+	// separately from Body because parseDocument tokenizes Body in place
+	// and often returns before scanning all of it, so Body.Len() after
+	// parsing no longer reflects what was actually fetched.
+	Bytes int
 }
 
 type DocumentPreview struct {
@@ -39,17 +95,56 @@ type DocumentPreview struct {
 	Link        string
 	Name        string
 	Icon        string
+
+	// Schema holds the first schema.org JSON-LD node with an @type found on
+	// the page, such as an Article, Product, VideoObject or BreadcrumbList.
+	Schema Schema
+	// Author is filled from the author or article:author meta tag,
+	// schema.org JSON-LD, or an oEmbed response's author_name, whichever is
+	// found first.
+	Author string
+	// PublishedAt is parsed from article:published_time or the JSON-LD
+	// datePublished field, whichever is found first.
+	PublishedAt time.Time
+	// Type is the page's og:type, falling back to twitter:card or the
+	// JSON-LD @type.
+	Type string
+	// Video is populated from twitter:player tags or an oEmbed response of
+	// type "video".
+	Video VideoPreview
+	// Keywords comes from the <meta name="keywords"> tag or schema.org
+	// JSON-LD keywords, whichever is found first.
+	Keywords []string
 }
 
 func Scrape(uri string, maxRedirect int, language, authorization string) (*Document, error) {
+	return ScrapeContext(context.Background(), uri, maxRedirect, language, authorization)
+}
+
+// ScrapeContext behaves like Scrape but binds every request the Scraper
+// makes to ctx, so callers can cancel a slow fetch or set a per-request
+// timeout with context.WithTimeout.
+func ScrapeContext(ctx context.Context, uri string, maxRedirect int, language, authorization string) (*Document, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
 	}
-	return (&Scraper{Url: u, Target: u, MaxRedirect: maxRedirect, Language: language, Authorization: authorization}).Scrape()
+	return (&Scraper{Url: u, Target: u, MaxRedirect: maxRedirect, Language: language, Authorization: authorization, Ctx: ctx}).Scrape()
 }
 
 func (scraper *Scraper) Scrape() (*Document, error) {
+	if scraper.RespectRobots {
+		cache := scraper.robotsCache()
+		// Allowed already fails open (returns true) when robots.txt can't be
+		// fetched, so a transient fetch error there must not abort the page
+		// fetch itself.
+		allowed, _ := cache.Allowed(scraper.ctx(), scraper.httpClient(), scraper.Url)
+		if !allowed {
+			return nil, fmt.Errorf("goscraper: %s disallowed by robots.txt", scraper.Url)
+		}
+		cache.Wait(scraper.ctx(), scraper.httpClient(), scraper.Url)
+	}
+
 	doc, err := scraper.getDocument()
 	if err != nil {
 		return nil, err
@@ -58,9 +153,51 @@ func (scraper *Scraper) Scrape() (*Document, error) {
 	if err != nil {
 		return nil, err
 	}
+	if scraper.ExtractArticle {
+		doc.Article = extractArticle(scraper.rawBody, scraper.sanitizePolicy())
+	}
 	return doc, nil
 }
 
+func (scraper *Scraper) robotsCache() *RobotsCache {
+	if scraper.Robots != nil {
+		return scraper.Robots
+	}
+	return defaultRobotsCache
+}
+
+func (scraper *Scraper) httpClient() *http.Client {
+	if scraper.Client != nil {
+		return scraper.Client
+	}
+	return http.DefaultClient
+}
+
+func (scraper *Scraper) ctx() context.Context {
+	if scraper.Ctx != nil {
+		return scraper.Ctx
+	}
+	return context.Background()
+}
+
+func (scraper *Scraper) userAgent() string {
+	if len(scraper.UserAgent) > 0 {
+		return scraper.UserAgent
+	}
+	return "GoScraper"
+}
+
+func (scraper *Scraper) visited(u string) bool {
+	return scraper.visitedUrls[u]
+}
+
+func (scraper *Scraper) markVisited(u string) {
+	if scraper.visitedUrls == nil {
+		scraper.visitedUrls = make(map[string]bool)
+	}
+	scraper.visitedUrls[u] = true
+}
+
 func (scraper *Scraper) getUrl() string {
 	if scraper.EscapedFragmentUrl != nil {
 		return scraper.EscapedFragmentUrl.String()
@@ -120,11 +257,11 @@ func (scraper *Scraper) getDocument() (*Document, error) {
 		scraper.EscapedFragmentUrl = scraper.Url
 	}
 
-	req, err := http.NewRequest("GET", scraper.getUrl(), nil)
+	req, err := http.NewRequestWithContext(scraper.ctx(), "GET", scraper.getUrl(), nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("User-Agent", "GoScraper")
+	req.Header.Add("User-Agent", scraper.userAgent())
 	if len(scraper.Authorization) > 0 {
 		cookie := "access_token=" + scraper.Authorization[7:] + "; refresh_token=" + scraper.Authorization[7:] + "; brainer_v4=true; expires_at=1947832244556; main_access_token=" + scraper.Authorization[7:] + "; main_refresh_token=" + scraper.Authorization[7:] + "; main_expires_at=1947832244556;"
 		req.Header.Set("Cookie", cookie)
@@ -134,7 +271,12 @@ func (scraper *Scraper) getDocument() (*Document, error) {
 	} else {
 		req.Header.Add("Accept-Language", "en")
 	}
-	resp, err := http.DefaultClient.Do(req)
+	for key, values := range scraper.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	resp, err := scraper.httpClient().Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -146,28 +288,18 @@ func (scraper *Scraper) getDocument() (*Document, error) {
 		scraper.EscapedFragmentUrl = nil
 		scraper.Url = resp.Request.URL
 	}
-	b, err := convertUTF8(resp.Body, resp.Header.Get("content-type"))
+	b, err := scraper.convertUTF8(resp.Body, resp.Header.Get("content-type"))
 	if err != nil {
 		return nil, err
 	}
-	doc := &Document{Body: b, Preview: DocumentPreview{Link: scraper.Url.String()}}
+	if scraper.ExtractArticle {
+		scraper.rawBody = append([]byte(nil), b.Bytes()...)
+	}
+	doc := &Document{Body: b, Bytes: b.Len(), Preview: DocumentPreview{Link: scraper.Url.String()}}
 
 	return doc, nil
 }
 
-func convertUTF8(content io.Reader, contentType string) (bytes.Buffer, error) {
-	buff := bytes.Buffer{}
-	content, err := charset.NewReader(content, contentType)
-	if err != nil {
-		return buff, err
-	}
-	_, err = io.Copy(&buff, content)
-	if err != nil {
-		return buff, err
-	}
-	return buff, nil
-}
-
 func (scraper *Scraper) parseDocument(doc *Document) error {
 	t := html.NewTokenizer(&doc.Body)
 	var ogImage bool
@@ -175,6 +307,8 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 	var hasFragment bool
 	var hasCanonical bool
 	var canonicalUrl *url.URL
+	var hasRefresh bool
+	var refreshUrl *url.URL
 	doc.Preview.Images = []string{}
 	// saves previews' link in case that <link rel="canonical"> is found after <meta property="og:url">
 	link := doc.Preview.Link
@@ -199,12 +333,22 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 			headPassed = true
 
 		case "link":
-			var canonical bool
+			var canonical, alternate, oembed bool
+			var oembedHref string
 			for _, attr := range token.Attr {
 				href := ""
 				if cleanStr(attr.Key) == "rel" && cleanStr(attr.Val) == "canonical" {
 					canonical = true
 				}
+				if cleanStr(attr.Key) == "rel" && cleanStr(attr.Val) == "alternate" {
+					alternate = true
+				}
+				if cleanStr(attr.Key) == "type" && cleanStr(attr.Val) == "application/json+oembed" {
+					oembed = true
+				}
+				if cleanStr(attr.Key) == "href" {
+					oembedHref = attr.Val
+				}
 				if cleanStr(attr.Key) == "rel" && (cleanStr(attr.Val) == "shortcut icon" || cleanStr(attr.Val) == "icon") && scraper.Url.Host == scraper.Target.Host {
 					for _, a := range token.Attr {
 						if a.Key == "href" {
@@ -234,6 +378,11 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 					}
 				}
 			}
+			if scraper.FetchOEmbed && alternate && oembed && len(oembedHref) > 0 {
+				if embed, err := fetchOEmbed(scraper.ctx(), scraper.httpClient(), oembedHref); err == nil {
+					applyOEmbed(&doc.Preview, embed)
+				}
+			}
 
 		case "meta":
 			if len(token.Attr) != 2 {
@@ -245,7 +394,7 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 			var property string
 			var content string
 			for _, attr := range token.Attr {
-				if cleanStr(attr.Key) == "property" || cleanStr(attr.Key) == "name" {
+				if cleanStr(attr.Key) == "property" || cleanStr(attr.Key) == "name" || cleanStr(attr.Key) == "http-equiv" {
 					property = attr.Val
 				}
 				if cleanStr(attr.Key) == "content" {
@@ -280,6 +429,77 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 
 				doc.Preview.Images = []string{ogImgUrl.String()}
 
+			case "og:type":
+				doc.Preview.Type = content
+			case "twitter:card":
+				if len(doc.Preview.Type) == 0 {
+					doc.Preview.Type = content
+				}
+			case "twitter:description":
+				if len(doc.Preview.Description) == 0 {
+					doc.Preview.Description = content
+				}
+			case "twitter:image":
+				if !ogImage {
+					doc.Preview.Images = append(doc.Preview.Images, content)
+				}
+			case "twitter:player":
+				doc.Preview.Video.URL = content
+				doc.Preview.Video.Type = "video"
+			case "twitter:player:width":
+				doc.Preview.Video.Width = content
+			case "twitter:player:height":
+				doc.Preview.Video.Height = content
+			case "author", "article:author":
+				if len(doc.Preview.Author) == 0 {
+					doc.Preview.Author = content
+				}
+			case "article:published_time":
+				if doc.Preview.PublishedAt.IsZero() {
+					if t, err := parseSchemaTime(content); err == nil {
+						doc.Preview.PublishedAt = t
+					}
+				}
+			case "keywords":
+				if len(doc.Preview.Keywords) == 0 {
+					doc.Preview.Keywords = splitKeywords(content)
+				}
+			case "refresh":
+				if target, err := parseMetaRefresh(content, scraper.Url); err == nil && target != nil {
+					hasRefresh = true
+					refreshUrl = target
+				}
+			}
+
+		case "script":
+			var isLDJSON bool
+			for _, attr := range token.Attr {
+				if cleanStr(attr.Key) == "type" && cleanStr(attr.Val) == "application/ld+json" {
+					isLDJSON = true
+				}
+			}
+			if isLDJSON && tokenType == html.StartTagToken {
+				t.Next()
+				text := t.Token()
+				if schema, ok := parseLDJSON([]byte(text.Data)); ok {
+					if len(doc.Preview.Schema.Type) == 0 {
+						doc.Preview.Schema = *schema
+					}
+					if len(doc.Preview.Type) == 0 {
+						doc.Preview.Type = schema.Type
+					}
+					if len(doc.Preview.Author) == 0 {
+						doc.Preview.Author = schema.Author
+					}
+					if len(doc.Preview.Keywords) == 0 {
+						doc.Preview.Keywords = schema.Keywords
+					}
+					if doc.Preview.PublishedAt.IsZero() {
+						if published, err := parseSchemaTime(schema.DatePublished); err == nil {
+							doc.Preview.PublishedAt = published
+						}
+					}
+				}
 			}
 
 		case "title":
@@ -336,6 +556,18 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 			return scraper.parseDocument(doc)
 		}
 
+		if hasRefresh && headPassed && scraper.MaxRedirect > 0 && !scraper.visited(refreshUrl.String()) {
+			scraper.markVisited(scraper.Url.String())
+			scraper.Url = refreshUrl
+			scraper.EscapedFragmentUrl = nil
+			fdoc, err := scraper.getDocument()
+			if err != nil {
+				return err
+			}
+			*doc = *fdoc
+			return scraper.parseDocument(doc)
+		}
+
 		if len(doc.Preview.Title) > 0 && len(doc.Preview.Description) > 0 && ogImage && headPassed {
 			return nil
 		}
@@ -359,6 +591,22 @@ func escapeByte(b byte) bool {
 	return false
 }
 
+// parseMetaRefresh parses a <meta http-equiv="refresh" content="..."> value
+// such as "0; url=https://example.com/next", resolving a relative target
+// against base. A refresh with no "url=" part (a plain delayed reload) is
+// not a redirect, so it returns a nil URL rather than an error.
+func parseMetaRefresh(content string, base *url.URL) (*url.URL, error) {
+	matches := metaRefreshRegexp.FindStringSubmatch(content)
+	if matches == nil {
+		return nil, nil
+	}
+	target, err := url.Parse(strings.TrimSpace(matches[1]))
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(target), nil
+}
+
 func metaFragment(token html.Token) bool {
 	var name string
 	var content string
@@ -0,0 +1,263 @@
+package goscraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RobotsUserAgent is the token goscraper matches against "User-agent:" groups
+// in robots.txt. It is independent of the HTTP User-Agent header sent with
+// requests (see Scraper.UserAgent).
+const RobotsUserAgent = "GoScraper"
+
+// DefaultRobotsCacheTTL controls how long a fetched robots.txt is considered
+// fresh before RobotsCache re-fetches it for a host.
+const DefaultRobotsCacheTTL = 1 * time.Hour
+
+// robotsRules holds the directives parsed out of a single robots.txt that
+// apply to RobotsUserAgent.
+type robotsRules struct {
+	disallow   []string
+	allow      []string
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+// allows reports whether path is permitted, using the longest-matching-rule
+// wins convention: the most specific Allow/Disallow prefix takes precedence,
+// and Allow wins ties.
+func (r *robotsRules) allows(path string) bool {
+	best := -1
+	bestAllow := true
+	for _, prefix := range r.disallow {
+		if strings.HasPrefix(path, prefix) && len(prefix) > best {
+			best = len(prefix)
+			bestAllow = false
+		}
+	}
+	for _, prefix := range r.allow {
+		if strings.HasPrefix(path, prefix) && len(prefix) >= best {
+			best = len(prefix)
+			bestAllow = true
+		}
+	}
+	return bestAllow
+}
+
+type robotsEntry struct {
+	rules      *robotsRules
+	fetchedAt  time.Time
+	lastAccess time.Time
+}
+
+// RobotsCache fetches and caches robots.txt on a per-host basis so repeated
+// Scrape calls against the same site share a single fetch within TTL, and so
+// Crawl-delay can be honored across calls.
+type RobotsCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+// NewRobotsCache returns a RobotsCache with the given TTL. A zero or negative
+// TTL falls back to DefaultRobotsCacheTTL.
+func NewRobotsCache(ttl time.Duration) *RobotsCache {
+	if ttl <= 0 {
+		ttl = DefaultRobotsCacheTTL
+	}
+	return &RobotsCache{TTL: ttl, entries: make(map[string]*robotsEntry)}
+}
+
+// defaultRobotsCache backs Scrapers that enable RespectRobots without
+// providing their own Robots cache.
+var defaultRobotsCache = NewRobotsCache(DefaultRobotsCacheTTL)
+
+// Allowed reports whether target may be fetched according to the cached
+// robots.txt for target's host, fetching it with client if the cache entry is
+// missing or stale. A robots.txt that can't be fetched is treated
+// permissively, matching how most crawlers fail open rather than stall. ctx
+// bounds the robots.txt fetch the same way it bounds the page fetch.
+func (c *RobotsCache) Allowed(ctx context.Context, client *http.Client, target *url.URL) (bool, error) {
+	entry, err := c.entryFor(ctx, client, target)
+	if err != nil {
+		return true, err
+	}
+	return entry.rules.allows(target.RequestURI()), nil
+}
+
+// Wait blocks, if necessary, until target's host's Crawl-delay has elapsed
+// since the last call to Wait for that host.
+func (c *RobotsCache) Wait(ctx context.Context, client *http.Client, target *url.URL) {
+	entry, err := c.entryFor(ctx, client, target)
+	if err != nil || entry.rules.crawlDelay <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	wait := entry.rules.crawlDelay - time.Since(entry.lastAccess)
+	entry.lastAccess = time.Now()
+	c.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// Sitemaps returns the Sitemap directives listed in target's host's
+// robots.txt, fetching it with client if the cache entry is missing or
+// stale.
+func (c *RobotsCache) Sitemaps(ctx context.Context, client *http.Client, target *url.URL) ([]string, error) {
+	entry, err := c.entryFor(ctx, client, target)
+	if err != nil {
+		return nil, err
+	}
+	return entry.rules.sitemaps, nil
+}
+
+func (c *RobotsCache) entryFor(ctx context.Context, client *http.Client, target *url.URL) (*robotsEntry, error) {
+	host := target.Host
+
+	c.mu.Lock()
+	entry := c.entries[host]
+	c.mu.Unlock()
+	if entry != nil && time.Since(entry.fetchedAt) < c.TTL {
+		return entry, nil
+	}
+
+	rules, err := fetchRobots(ctx, client, target)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing := c.entries[host]; existing != nil {
+		// Preserve lastAccess across refetches so Crawl-delay keeps pacing.
+		entry = &robotsEntry{rules: rules, fetchedAt: time.Now(), lastAccess: existing.lastAccess}
+	} else {
+		entry = &robotsEntry{rules: rules, fetchedAt: time.Now()}
+	}
+	c.entries[host] = entry
+	return entry, nil
+}
+
+func fetchRobots(ctx context.Context, client *http.Client, target *url.URL) (*robotsRules, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	robotsUrl := fmt.Sprintf("%s://%s/robots.txt", target.Scheme, target.Host)
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		// No robots.txt, or the host errored: nothing to disallow.
+		return &robotsRules{}, nil
+	}
+	return parseRobots(resp.Body), nil
+}
+
+// parseRobots parses robots.txt content, keeping only the directives that
+// apply to RobotsUserAgent: the matching "User-agent: GoScraper" group if one
+// exists anywhere in the file, falling back to "User-agent: *" groups
+// otherwise. Group precedence doesn't depend on which appears first in the
+// file — a wildcard block listed before the GoScraper-specific one must
+// still be ignored, not merged in.
+func parseRobots(body io.Reader) *robotsRules {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return &robotsRules{}
+	}
+
+	hasTargetGroup := robotsHasTargetGroup(data)
+
+	rules := &robotsRules{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	var inWildcardGroup, inTargetGroup bool
+	for scanner.Scan() {
+		line := stripRobotsComment(scanner.Text())
+		key, value, ok := splitRobotsDirective(line)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "user-agent":
+			if strings.TrimSpace(value) == "*" {
+				inWildcardGroup, inTargetGroup = true, false
+			} else if strings.EqualFold(strings.TrimSpace(value), RobotsUserAgent) {
+				inWildcardGroup, inTargetGroup = false, true
+			} else {
+				inWildcardGroup, inTargetGroup = false, false
+			}
+		case "disallow":
+			if (inTargetGroup || (inWildcardGroup && !hasTargetGroup)) && value != "" {
+				rules.disallow = append(rules.disallow, value)
+			}
+		case "allow":
+			if (inTargetGroup || (inWildcardGroup && !hasTargetGroup)) && value != "" {
+				rules.allow = append(rules.allow, value)
+			}
+		case "crawl-delay":
+			if inTargetGroup || (inWildcardGroup && !hasTargetGroup) {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+					rules.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			rules.sitemaps = append(rules.sitemaps, value)
+		}
+	}
+	return rules
+}
+
+// robotsHasTargetGroup reports whether data contains a
+// "User-agent: GoScraper" group anywhere, so the main parse pass can tell a
+// wildcard group apart from the group that should actually take precedence,
+// regardless of which one appears first in the file.
+func robotsHasTargetGroup(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := splitRobotsDirective(stripRobotsComment(scanner.Text()))
+		if !ok {
+			continue
+		}
+		if strings.ToLower(key) == "user-agent" && strings.EqualFold(strings.TrimSpace(value), RobotsUserAgent) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripRobotsComment(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+func splitRobotsDirective(line string) (key, value string, ok bool) {
+	i := strings.IndexByte(line, ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
@@ -0,0 +1,63 @@
+package goscraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRobotsGroupPrecedence(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		disallow []string
+	}{
+		{
+			name: "target group after wildcard group is not merged with it",
+			body: "User-agent: *\n" +
+				"Disallow: /all\n" +
+				"\n" +
+				"User-agent: GoScraper\n" +
+				"Disallow: /private\n",
+			disallow: []string{"/private"},
+		},
+		{
+			name: "target group before wildcard group is still preferred",
+			body: "User-agent: GoScraper\n" +
+				"Disallow: /private\n" +
+				"\n" +
+				"User-agent: *\n" +
+				"Disallow: /all\n",
+			disallow: []string{"/private"},
+		},
+		{
+			name: "wildcard group applies when no target group exists",
+			body: "User-agent: *\n" +
+				"Disallow: /all\n",
+			disallow: []string{"/all"},
+		},
+		{
+			name: "unrelated bot group is ignored",
+			body: "User-agent: OtherBot\n" +
+				"Disallow: /other\n" +
+				"\n" +
+				"User-agent: GoScraper\n" +
+				"Disallow: /private\n",
+			disallow: []string{"/private"},
+		},
+		{
+			name:     "matching is case-insensitive",
+			body:     "User-agent: goscraper\nDisallow: /private\n",
+			disallow: []string{"/private"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(c.body))
+			if !reflect.DeepEqual(rules.disallow, c.disallow) {
+				t.Errorf("disallow = %v, want %v", rules.disallow, c.disallow)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package goscraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Schema holds the schema.org fields goscraper recognizes from a page's
+// JSON-LD blocks. Any node with a non-empty @type is accepted and handled
+// the same way, whether it is an Article, Product, VideoObject,
+// BreadcrumbList or another type: Price/PriceCurrency come from its offers
+// and Breadcrumbs from its itemListElement.
+type Schema struct {
+	Type          string
+	Headline      string
+	Author        string
+	DatePublished string
+	DateModified  string
+	Images        []string
+	Keywords      []string
+	Price         string
+	PriceCurrency string
+	Breadcrumbs   []string
+}
+
+// VideoPreview describes a playable video surfaced via twitter:player or an
+// oEmbed response of type "video".
+type VideoPreview struct {
+	URL    string
+	Width  string
+	Height string
+	Type   string
+}
+
+// oEmbed is the subset of the oEmbed spec (https://oembed.com) goscraper
+// uses to fill in Preview.Author, Title, Images and Video.
+type oEmbed struct {
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	HTML         string `json:"html"`
+	URL          string `json:"url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+type ldNode struct {
+	Type            interface{}       `json:"@type"`
+	Headline        string            `json:"headline"`
+	Name            string            `json:"name"`
+	Author          json.RawMessage   `json:"author"`
+	DatePublished   string            `json:"datePublished"`
+	DateModified    string            `json:"dateModified"`
+	Image           json.RawMessage   `json:"image"`
+	Keywords        interface{}       `json:"keywords"`
+	Offers          json.RawMessage   `json:"offers"`
+	ItemListElement []ldListItem      `json:"itemListElement"`
+	Graph           []json.RawMessage `json:"@graph"`
+}
+
+type ldListItem struct {
+	Name string `json:"name"`
+}
+
+type ldOffer struct {
+	Price         interface{} `json:"price"`
+	PriceCurrency string      `json:"priceCurrency"`
+}
+
+// parseLDJSON parses a single <script type="application/ld+json"> block. It
+// accepts a lone object, an array of objects, or a top-level "@graph", and
+// returns the first node whose @type goscraper recognizes.
+func parseLDJSON(raw []byte) (*Schema, bool) {
+	var nodes []ldNode
+
+	var single ldNode
+	if err := json.Unmarshal(raw, &single); err == nil && (single.Type != nil || len(single.Graph) > 0) {
+		if len(single.Graph) > 0 {
+			for _, g := range single.Graph {
+				var n ldNode
+				if json.Unmarshal(g, &n) == nil {
+					nodes = append(nodes, n)
+				}
+			}
+		} else {
+			nodes = append(nodes, single)
+		}
+	} else {
+		var arr []ldNode
+		if json.Unmarshal(raw, &arr) == nil {
+			nodes = arr
+		}
+	}
+
+	for _, n := range nodes {
+		if schema, ok := schemaFromNode(n); ok {
+			return schema, true
+		}
+	}
+	return nil, false
+}
+
+func schemaFromNode(n ldNode) (*Schema, bool) {
+	t := ldTypeString(n.Type)
+	switch t {
+	case "Article", "NewsArticle", "BlogPosting", "Product", "VideoObject", "BreadcrumbList":
+	default:
+		if t == "" {
+			return nil, false
+		}
+	}
+
+	s := &Schema{
+		Type:          t,
+		Headline:      firstNonEmpty(n.Headline, n.Name),
+		Author:        ldAuthorName(n.Author),
+		DatePublished: n.DatePublished,
+		DateModified:  n.DateModified,
+		Images:        ldStringList(n.Image),
+		Keywords:      ldKeywords(n.Keywords),
+	}
+	if len(n.Offers) > 0 {
+		var offer ldOffer
+		if json.Unmarshal(n.Offers, &offer) == nil {
+			s.Price = fmt.Sprintf("%v", offer.Price)
+			s.PriceCurrency = offer.PriceCurrency
+		}
+	}
+	for _, item := range n.ItemListElement {
+		if item.Name != "" {
+			s.Breadcrumbs = append(s.Breadcrumbs, item.Name)
+		}
+	}
+	return s, true
+}
+
+func ldTypeString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		if len(t) > 0 {
+			if s, ok := t[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func ldAuthorName(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(raw, &obj) == nil {
+		return obj.Name
+	}
+	var list []struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(raw, &list) == nil && len(list) > 0 {
+		return list[0].Name
+	}
+	return ""
+}
+
+func ldStringList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return []string{s}
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+	var obj struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(raw, &obj) == nil && obj.URL != "" {
+		return []string{obj.URL}
+	}
+	var objs []struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(raw, &objs) == nil {
+		urls := make([]string, 0, len(objs))
+		for _, o := range objs {
+			if o.URL != "" {
+				urls = append(urls, o.URL)
+			}
+		}
+		return urls
+	}
+	return nil
+}
+
+func ldKeywords(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return splitKeywords(t)
+	case []interface{}:
+		keywords := make([]string, 0, len(t))
+		for _, k := range t {
+			if s, ok := k.(string); ok && strings.TrimSpace(s) != "" {
+				keywords = append(keywords, strings.TrimSpace(s))
+			}
+		}
+		return keywords
+	}
+	return nil
+}
+
+func splitKeywords(s string) []string {
+	parts := strings.Split(s, ",")
+	keywords := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			keywords = append(keywords, p)
+		}
+	}
+	return keywords
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseSchemaTime parses the date formats schema.org and OpenGraph articles
+// commonly use for datePublished/article:published_time.
+func parseSchemaTime(s string) (time.Time, error) {
+	layouts := []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02"}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// applyOEmbed fills in whatever preview fields the page's own metadata left
+// empty, using an oEmbed payload fetched via a <link rel="alternate"
+// type="application/json+oembed">. For a "video" response its url, width and
+// height replace any twitter:player values already set.
+func applyOEmbed(preview *DocumentPreview, embed *oEmbed) {
+	if len(preview.Author) == 0 {
+		preview.Author = embed.AuthorName
+	}
+	if len(preview.Title) == 0 {
+		preview.Title = embed.Title
+	}
+	if len(preview.Name) == 0 {
+		preview.Name = embed.ProviderName
+	}
+	if embed.ThumbnailURL != "" && len(preview.Images) == 0 {
+		preview.Images = []string{embed.ThumbnailURL}
+	}
+	if embed.Type == "video" {
+		video := VideoPreview{URL: firstNonEmpty(embed.URL, preview.Video.URL), Type: embed.Type}
+		video.Width = preview.Video.Width
+		video.Height = preview.Video.Height
+		if embed.Width > 0 {
+			video.Width = fmt.Sprintf("%d", embed.Width)
+		}
+		if embed.Height > 0 {
+			video.Height = fmt.Sprintf("%d", embed.Height)
+		}
+		preview.Video = video
+	}
+}
+
+// fetchOEmbed retrieves and decodes the oEmbed payload at href, as pointed to
+// by a page's <link rel="alternate" type="application/json+oembed">. ctx
+// bounds the request the same way it bounds the page fetch.
+func fetchOEmbed(ctx context.Context, client *http.Client, href string) (*oEmbed, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", href, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("goscraper: oembed fetch failed with status %d", resp.StatusCode)
+	}
+	var payload oEmbed
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}